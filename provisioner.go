@@ -7,8 +7,10 @@ import (
   "encoding/json"
   "fmt"
   "io"
+  "io/ioutil"
   "log"
   "os"
+  "os/exec"
   "path/filepath"
   "strings"
   "text/template"
@@ -23,9 +25,7 @@ import (
   "github.com/mitchellh/go-linereader"
 )
 
-const (
-  bootstrapDirectory string = "/tmp/ansible-terraform-bootstrap"
-)
+const bootstrapDirectory string = "/tmp/ansible-terraform-bootstrap"
 
 const installerProgramTemplate = `#!/usr/bin/env bash
 if [ -z "$(which ansible-playbook)" ]; then
@@ -65,7 +65,100 @@ else
       pip install $expected_version
     fi
   fi
-  
+
+fi
+`
+
+// installerProgramTemplatePipVenv installs a pinned ansible-core into an
+// isolated virtualenv rather than system Python, sidestepping the PEP 668
+// externally-managed-environment lockout on Debian 12+/Ubuntu 23.04+.
+const installerProgramTemplatePipVenv = `#!/usr/bin/env bash
+set -e
+
+if [ -z "$(which ansible-playbook)" ]; then
+
+  # only check the cloud boot finished if the directory exists
+  if [ -d /var/lib/cloud/instance ]; then
+    until [[ -f /var/lib/cloud/instance/boot-finished ]]; do
+      sleep 1
+    done
+  fi
+
+  if [ -z "$(which python3)" ]; then
+    if [[ -f /etc/redhat-release ]]; then
+      yum install -y python3
+    else
+      apt-get update && apt-get install -y python3 python3-venv
+    fi
+  fi
+
+  python3 -m venv /opt/ansible-venv
+  /opt/ansible-venv/bin/pip install --upgrade pip
+  /opt/ansible-venv/bin/pip install {{ .AnsibleVersion }}
+
+  ln -sf /opt/ansible-venv/bin/ansible /usr/local/bin/ansible
+  ln -sf /opt/ansible-venv/bin/ansible-playbook /usr/local/bin/ansible-playbook
+  ln -sf /opt/ansible-venv/bin/ansible-galaxy /usr/local/bin/ansible-galaxy
+fi
+`
+
+// installerProgramTemplatePackage installs Ansible from the distro's own
+// package manager, detected from /etc/os-release, instead of pip.
+const installerProgramTemplatePackage = `#!/usr/bin/env bash
+set -e
+
+if [ -z "$(which ansible-playbook)" ]; then
+
+  # only check the cloud boot finished if the directory exists
+  if [ -d /var/lib/cloud/instance ]; then
+    until [[ -f /var/lib/cloud/instance/boot-finished ]]; do
+      sleep 1
+    done
+  fi
+
+  . /etc/os-release
+
+  case "$ID" in
+    debian|ubuntu)
+      apt-get update && apt-get install -y ansible
+      ;;
+    rhel|centos|fedora|amzn)
+      dnf install -y ansible 2>/dev/null || yum install -y ansible
+      ;;
+    alpine)
+      apk add --no-cache ansible
+      ;;
+    *)
+      echo "install_method=package: unsupported distro '$ID'" >&2
+      exit 1
+      ;;
+  esac
+fi
+`
+
+// installerProgramTemplatePipx installs Ansible via pipx, which gives it
+// its own isolated environment without requiring a bespoke venv path.
+const installerProgramTemplatePipx = `#!/usr/bin/env bash
+set -e
+
+if [ -z "$(which ansible-playbook)" ]; then
+
+  if [ -d /var/lib/cloud/instance ]; then
+    until [[ -f /var/lib/cloud/instance/boot-finished ]]; do
+      sleep 1
+    done
+  fi
+
+  if [ -z "$(which pipx)" ]; then
+    if [[ -f /etc/redhat-release ]]; then
+      yum install -y pipx
+    else
+      apt-get update && apt-get install -y pipx
+    fi
+  fi
+
+  pipx install {{ .AnsibleVersion }}
+  pipx ensurepath
 fi
 `
 
@@ -84,12 +177,62 @@ const inventoryTemplate = `{{$top := . -}}
 
 var inventoryFilePath string = filepath.Join(bootstrapDirectory, ".inventory-ansible-bootstrap/hosts")
 
+// inventoryTemplateLocal renders the inventory used when the playbook is
+// run from the Terraform host itself against an SSH target (see
+// provisioner.local) — opt-in via `local = true`, pointing ansible at the
+// provisioned resource over SSH instead of connecting locally on the
+// target. WinRM targets render a different inventory; see
+// inventoryTemplateLocalWinRM.
+const inventoryTemplateLocal = `{{.Host}} ansible_host={{.Host}} ansible_port={{.Port}} ansible_user={{.User}}{{if .PrivateKeyFile}} ansible_ssh_private_key_file={{.PrivateKeyFile}}{{end}}{{if .SSHCommonArgs}} ansible_ssh_common_args='{{.SSHCommonArgs}}'{{end}}
+`
+
+type localInventoryData struct {
+  Host           string
+  Port           string
+  User           string
+  PrivateKeyFile string
+  SSHCommonArgs  string
+}
+
+// inventoryTemplateLocalWinRM renders the inventory used in local-exec mode
+// against a WinRM target (see writeLocalInventoryWinRM): the SSH-oriented
+// vars above (private key, ssh_common_args) have no WinRM equivalent, so
+// this is a separate template rather than a conditional branch of
+// inventoryTemplateLocal.
+const inventoryTemplateLocalWinRM = `{{.Host}} ansible_host={{.Host}} ansible_connection=winrm ansible_port={{.Port}} ansible_user={{.User}} ansible_password={{.Password}} ansible_winrm_scheme={{.Scheme}} ansible_winrm_transport={{.WinRMTransport}} ansible_winrm_server_cert_validation={{.ServerCertValidation}}
+`
+
+type winrmInventoryData struct {
+  Host                 string
+  Port                 string
+  User                 string
+  Password             string
+  Scheme               string
+  WinRMTransport       string
+  ServerCertValidation string
+}
+
 type ansibleInstaller struct {
   AnsibleVersion string
 }
 
+// play holds the per-playbook overrides of a single `plays` block. When no
+// `plays` blocks are configured, the provisioner's legacy top-level
+// Playbook/Tags/... fields are wrapped into a single implicit play so both
+// configuration styles run through the same execution path.
+type play struct {
+  Playbook        string
+  Tags            []string
+  SkipTags        []string
+  StartAtTask     string
+  Limit           string
+  ExtraVars       map[string]interface{}
+  ContinueOnError bool
+}
+
 type provisioner struct {
   Playbook          string
+  Plays             []*play
   Hosts             []string
   Groups            []string
   Tags              []string
@@ -111,6 +254,56 @@ type provisioner struct {
   skipInstall       bool
   skipCleanup       bool
   installVersion    string
+  local             bool
+  hostKeyChecking   bool
+  knownHosts        string
+
+  galaxyRequirements    string
+  galaxyRolesPath       string
+  galaxyCollectionsPath string
+  galaxyForce           bool
+  galaxyIgnoreCerts     bool
+
+  stdoutCallback     string
+  callbackOutputPath string
+
+  installMethod        string
+  customInstallScript  string
+  pythonInterpreter    string
+
+  connType          string
+}
+
+// ansibleRunResult is the machine-readable surface of an ansible-playbook
+// run, produced whenever stdout_callback is set and mirrored onto the
+// provisioner's computed schema fields so downstream config can react to
+// e.g. changed > 0. The counts are parsed only for the "json" callback;
+// for any other callback they stay zero and only RawOutput is filled.
+type ansibleRunResult struct {
+  OK          int
+  Changed     int
+  Failed      int
+  Unreachable int
+  Skipped     int
+  RawOutput   string
+}
+
+type ansibleJSONStats struct {
+  Ok          int `json:"ok"`
+  Changed     int `json:"changed"`
+  Unreachable int `json:"unreachable"`
+  Failures    int `json:"failures"`
+  Skipped     int `json:"skipped"`
+}
+
+type ansibleJSONCallback struct {
+  Stats map[string]ansibleJSONStats `json:"stats"`
+}
+
+// isWindowsTarget reports whether the communicator is configured for WinRM,
+// mirroring the connection-type detection used by the Chef provisioner.
+func (p *provisioner) isWindowsTarget() bool {
+  return p.connType == "winrm"
 }
 
 func Provisioner() terraform.ResourceProvisioner {
@@ -121,6 +314,47 @@ func Provisioner() terraform.ResourceProvisioner {
         Optional: true,
         Default: "~/ansible/playbook.yaml",
       },
+      "plays": &schema.Schema{
+        Type:     schema.TypeList,
+        Optional: true,
+        Elem: &schema.Resource{
+          Schema: map[string]*schema.Schema{
+            "playbook": &schema.Schema{
+              Type:     schema.TypeString,
+              Required: true,
+            },
+            "tags": &schema.Schema{
+              Type:     schema.TypeList,
+              Elem:     &schema.Schema{Type: schema.TypeString},
+              Optional: true,
+            },
+            "skip_tags": &schema.Schema{
+              Type:     schema.TypeList,
+              Elem:     &schema.Schema{Type: schema.TypeString},
+              Optional: true,
+            },
+            "start_at_task": &schema.Schema{
+              Type:     schema.TypeString,
+              Optional: true,
+              Default:  "",
+            },
+            "limit": &schema.Schema{
+              Type:     schema.TypeString,
+              Optional: true,
+              Default:  "",
+            },
+            "extra_vars": &schema.Schema{
+              Type:     schema.TypeMap,
+              Optional: true,
+            },
+            "continue_on_error": &schema.Schema{
+              Type:     schema.TypeBool,
+              Optional: true,
+              Default:  false,
+            },
+          },
+        },
+      },
       "hosts": &schema.Schema{
         Type:     schema.TypeList,
         Elem:     &schema.Schema{ Type: schema.TypeString },
@@ -214,6 +448,98 @@ func Provisioner() terraform.ResourceProvisioner {
         Optional: true,
         Default:  "", // latest
       },
+      "local": &schema.Schema{
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
+      "host_key_checking": &schema.Schema{
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  true,
+      },
+      "known_hosts": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "",
+      },
+
+      "galaxy_requirements": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "",
+      },
+      "galaxy_roles_path": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "",
+      },
+      "galaxy_collections_path": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "",
+      },
+      "galaxy_force": &schema.Schema{
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
+      "galaxy_ignore_certs": &schema.Schema{
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+      },
+
+      "stdout_callback": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "", // "json" or "yaml"; empty keeps the default human-readable callback
+      },
+      "callback_output_path": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "",
+      },
+      "ansible_ok": &schema.Schema{
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      "ansible_changed": &schema.Schema{
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      "ansible_failed": &schema.Schema{
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      "ansible_unreachable": &schema.Schema{
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      "ansible_skipped": &schema.Schema{
+        Type:     schema.TypeInt,
+        Computed: true,
+      },
+      "ansible_result_json": &schema.Schema{
+        Type:     schema.TypeString,
+        Computed: true,
+      },
+
+      "install_method": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "pip", // one of: pip, pip_venv, package, pipx, custom_script
+      },
+      "custom_install_script": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "",
+      },
+      "python_interpreter": &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+        Default:  "",
+      },
     },
     ApplyFunc:    applyFn,
   }
@@ -231,6 +557,29 @@ func applyFn(ctx context.Context) error {
     return err
   }
 
+  // Detect the connection type the same way the Chef provisioner does, so
+  // we can dispatch to the right platform-specific implementation below.
+  if s.Ephemeral.ConnInfo != nil {
+    p.connType = s.Ephemeral.ConnInfo["type"]
+  }
+  if p.isWindowsTarget() && !p.local {
+    // Ansible does not support running ansible-playbook/ansible-galaxy from
+    // a Windows control node, so installing Ansible on a WinRM target and
+    // running it there (ansible_connection=local) is not viable. Always run
+    // from the Terraform host against the target over WinRM instead, the
+    // same way `local = true` does for SSH targets.
+    o.Output("WinRM target detected: running Ansible from the Terraform host against it over WinRM (local mode), since Ansible cannot run on Windows itself.")
+    p.local = true
+  }
+
+  if p.local {
+    // ansible-playbook runs on the Terraform host itself, so there is no
+    // target communicator to install Ansible on or upload a module to.
+    result, err := p.runAnsibleLocal(o, s)
+    setAnsibleResult(d, result)
+    return err
+  }
+
   // Get a new communicator
   comm, err := communicator.New(s)
   if err != nil {
@@ -251,7 +600,9 @@ func applyFn(ctx context.Context) error {
     }
   }
 
-  if err := p.deployAnsibleModule(o, comm); err != nil {
+  result, err := p.deployAnsibleModule(o, comm)
+  setAnsibleResult(d, result)
+  if err != nil {
     o.Output(fmt.Sprintf("%+v", err))
     return err
   }
@@ -260,72 +611,633 @@ func applyFn(ctx context.Context) error {
 
 }
 
-func (p *provisioner) deployAnsibleModule(o terraform.UIOutput, comm communicator.Communicator) error {
-  
-  playbookPath, err := p.resolvePath(p.Playbook, o)
-  if err != nil {
-    return err
+// setAnsibleResult mirrors a parsed ansible-playbook run onto the
+// provisioner's computed schema fields, so e.g. `changed > 0` can drive
+// downstream Terraform logic. A nil result (no stdout_callback configured)
+// is a no-op.
+func setAnsibleResult(d *schema.ResourceData, result *ansibleRunResult) {
+  if result == nil {
+    return
   }
+  d.Set("ansible_ok", result.OK)
+  d.Set("ansible_changed", result.Changed)
+  d.Set("ansible_failed", result.Failed)
+  d.Set("ansible_unreachable", result.Unreachable)
+  d.Set("ansible_skipped", result.Skipped)
+  d.Set("ansible_result_json", result.RawOutput)
+}
 
-  // playbook file is at the top level of the module
-  // parse the playbook path's directory and upload the entire directory
-  playbookDir := filepath.Dir(playbookPath)
+// runAnsibleLocal runs ansible-playbook on the machine executing Terraform,
+// rendering an inventory that points back at the resource over the
+// connection info negotiated for it — SSH normally, or WinRM when
+// isWindowsTarget() (Ansible cannot run on the Windows target itself, so
+// WinRM targets always take this path; see applyFn). It requires neither
+// Ansible nor Python on the target, which matters for minimal/immutable
+// images as well as Windows.
+func (p *provisioner) runAnsibleLocal(o terraform.UIOutput, s *terraform.InstanceState) (*ansibleRunResult, error) {
+  connInfo := s.Ephemeral.ConnInfo
 
-  remotePlaybookPath := filepath.Join(bootstrapDirectory, filepath.Base(playbookPath))
+  plays := p.plays()
 
-  // upload ansible source and playbook to the host
-  if err := comm.UploadDir(bootstrapDirectory, playbookDir); err != nil {
-    return err
+  tempDir, err := ioutil.TempDir("", "terraform-provisioner-ansible")
+  if err != nil {
+    return nil, fmt.Errorf("Error creating local temp directory: %s", err)
+  }
+  defer os.RemoveAll(tempDir)
+
+  inventoryPath := filepath.Join(tempDir, "hosts")
+  var ansibleCfgPath string
+
+  if p.isWindowsTarget() {
+    // WinRM has no equivalent of SSH key files, known_hosts, or a
+    // ProxyCommand-based bastion hop, so skip straight to a plain
+    // ansible.cfg and a WinRM-flavored inventory.
+    ansibleCfgPath = filepath.Join(tempDir, "ansible.cfg")
+    if err := ioutil.WriteFile(ansibleCfgPath, []byte("[defaults]\nhost_key_checking = False\n"), 0644); err != nil {
+      return nil, fmt.Errorf("Error writing ansible.cfg: %s", err)
+    }
+    if err := p.writeLocalInventoryWinRM(inventoryPath, connInfo); err != nil {
+      return nil, err
+    }
+  } else {
+    privateKeyPath, err := writeLocalKeyFile(tempDir, "id_ansible", connInfo["private_key"])
+    if err != nil {
+      return nil, err
+    }
+    bastionKeyPath, err := writeLocalKeyFile(tempDir, "id_ansible_bastion", connInfo["bastion_private_key"])
+    if err != nil {
+      return nil, err
+    }
+
+    var knownHostsPath string
+    knownHostsPath, ansibleCfgPath, err = p.prepareHostKeyChecking(o, tempDir, connInfo)
+    if err != nil {
+      return nil, err
+    }
+
+    if err := p.writeLocalInventory(inventoryPath, connInfo, privateKeyPath, bastionKeyPath, knownHostsPath); err != nil {
+      return nil, err
+    }
   }
 
   vaultPasswordFilePath := p.VaultPasswordFile
-  uploadedVaultPasswordFilePath := ""
   if len(vaultPasswordFilePath) > 0 {
     vaultPasswordFilePath, err = p.resolvePath(vaultPasswordFilePath, o)
     if err != nil {
-      return err
+      return nil, err
     }
-    uploadedVaultPasswordFilePath, err = p.uploadVaultPasswordFile(o, comm, vaultPasswordFilePath)
+  }
+
+  env := []string{fmt.Sprintf("ANSIBLE_CONFIG=%s", ansibleCfgPath)}
+  if !p.hostKeyChecking {
+    env = append(env, "ANSIBLE_HOST_KEY_CHECKING=False")
+  }
+
+  if len(p.galaxyRequirements) > 0 {
+    galaxyRequirementsPath, err := p.resolvePath(p.galaxyRequirements, o)
     if err != nil {
-      return err
+      return nil, err
+    }
+    for _, galaxyCommand := range p.galaxyCommands(galaxyRequirementsPath, galaxyRequirementsPath) {
+      o.Output(fmt.Sprintf("running local command: %s", galaxyCommand))
+      if err := p.runLocalCommand(o, galaxyCommand, env, nil); err != nil {
+        return nil, err
+      }
+    }
+  }
+
+  if len(p.stdoutCallback) > 0 {
+    env = append(env, fmt.Sprintf("ANSIBLE_STDOUT_CALLBACK=%s", p.stdoutCallback))
+  }
+
+  var result *ansibleRunResult
+  for i, pl := range plays {
+    playbookPath, err := p.resolvePath(pl.Playbook, o)
+    if err != nil {
+      return result, err
+    }
+
+    command, err := p.commandBuilder(pl, playbookPath, inventoryPath, vaultPasswordFilePath)
+    if err != nil {
+      return result, err
+    }
+
+    o.Output(fmt.Sprintf("running local command (play %d/%d): %s", i+1, len(plays), command))
+
+    var capture *bytes.Buffer
+    if len(p.stdoutCallback) > 0 {
+      capture = &bytes.Buffer{}
+    }
+    runErr := p.runLocalCommand(o, command, env, capture)
+
+    playResult, parseErr := p.finishCallbackOutput(o, capture)
+    if parseErr != nil {
+      o.Output(fmt.Sprintf("Warning: could not parse ansible %s output: %s", p.stdoutCallback, parseErr))
+    }
+    result = mergeAnsibleResult(result, playResult)
+
+    if runErr != nil {
+      if pl.ContinueOnError {
+        o.Output(fmt.Sprintf("play %d/%d failed, continuing because continue_on_error is set: %s", i+1, len(plays), runErr))
+        continue
+      }
+      return result, runErr
+    }
+  }
+
+  return result, nil
+}
+
+// galaxyCommands builds the ansible-galaxy invocations needed to satisfy
+// galaxy_requirements before the playbook runs: a role install always, plus
+// a collection install when the requirements file declares collections.
+// remoteRequirementsPath is where ansible-galaxy will read the file from
+// (which may differ from localRequirementsPath when run against a remote
+// target); localRequirementsPath is always a local, readable path used only
+// to sniff the file for a top-level "collections:" key.
+func (p *provisioner) galaxyCommands(remoteRequirementsPath string, localRequirementsPath string) []string {
+  commands := []string{p.galaxyInstallCommand("", remoteRequirementsPath)}
+  if requirementsHasCollections(localRequirementsPath) {
+    commands = append(commands, p.galaxyInstallCommand("collection", remoteRequirementsPath))
+  }
+  return commands
+}
+
+// galaxyInstallCommand builds either a role install (subcommand == "") or a
+// collection install (subcommand == "collection"). The two take different
+// flags for where to install to, so the path flag is chosen per subcommand
+// rather than shared: "ansible-galaxy install" takes --roles-path, while
+// "ansible-galaxy collection install" only understands -p/--collections-path
+// and errors out on --roles-path.
+func (p *provisioner) galaxyInstallCommand(subcommand string, requirementsPath string) string {
+  command := "ansible-galaxy"
+  if len(subcommand) > 0 {
+    command = fmt.Sprintf("%s %s", command, subcommand)
+  }
+  command = fmt.Sprintf("%s install -r %s", command, requirementsPath)
+  if subcommand == "collection" {
+    if len(p.galaxyCollectionsPath) > 0 {
+      command = fmt.Sprintf("%s --collections-path=%s", command, p.galaxyCollectionsPath)
     }
+  } else if len(p.galaxyRolesPath) > 0 {
+    command = fmt.Sprintf("%s --roles-path=%s", command, p.galaxyRolesPath)
+  }
+  if p.galaxyForce {
+    command = fmt.Sprintf("%s --force", command)
   }
+  if p.galaxyIgnoreCerts {
+    command = fmt.Sprintf("%s --ignore-certs", command)
+  }
+  return command
+}
 
-  // build a command to run ansible on the host machine
-  command, err := p.commandBuilder(remotePlaybookPath, uploadedVaultPasswordFilePath)
+// requirementsHasCollections does a light-weight scan for a top-level
+// "collections:" key, avoiding a dependency on a full YAML parser just to
+// decide whether ansible-galaxy collection install is needed.
+func requirementsHasCollections(path string) bool {
+  content, err := ioutil.ReadFile(path)
   if err != nil {
-    return err
+    return false
+  }
+  for _, line := range strings.Split(string(content), "\n") {
+    if strings.HasPrefix(line, "collections:") {
+      return true
+    }
+  }
+  return false
+}
+
+// prepareHostKeyChecking renders an ansible.cfg honoring host_key_checking
+// and, when checking is enabled, a known_hosts file seeded either from the
+// known_hosts schema field or a live ssh-keyscan of the target. Supplying
+// known_hosts pins the key to a value the caller actually trusts; the
+// ssh-keyscan fallback is trust-on-first-use over its own, separate
+// connection to the target and does not itself rule out a MITM — it is
+// only a strictly better default than running with host key checking off
+// entirely. Users who need real protection against key substitution
+// should supply known_hosts.
+func (p *provisioner) prepareHostKeyChecking(o terraform.UIOutput, tempDir string, connInfo map[string]string) (string, string, error) {
+  ansibleCfgPath := filepath.Join(tempDir, "ansible.cfg")
+
+  if !p.hostKeyChecking {
+    cfg := "[defaults]\nhost_key_checking = False\n"
+    if err := ioutil.WriteFile(ansibleCfgPath, []byte(cfg), 0644); err != nil {
+      return "", "", fmt.Errorf("Error writing ansible.cfg: %s", err)
+    }
+    return "", ansibleCfgPath, nil
+  }
+
+  knownHostsPath := filepath.Join(tempDir, "known_hosts")
+  if len(p.knownHosts) > 0 {
+    knownHostsSrc, err := p.resolvePath(p.knownHosts, o)
+    if err != nil {
+      return "", "", err
+    }
+    content, err := ioutil.ReadFile(knownHostsSrc)
+    if err != nil {
+      return "", "", fmt.Errorf("Error reading known_hosts file: %s", err)
+    }
+    if err := ioutil.WriteFile(knownHostsPath, content, 0644); err != nil {
+      return "", "", fmt.Errorf("Error writing known_hosts file: %s", err)
+    }
+  } else {
+    o.Output(fmt.Sprintf("Scanning host key for %s...", connInfo["host"]))
+    if err := scanHostKey(knownHostsPath, connInfo["host"], connInfo["port"]); err != nil {
+      return "", "", err
+    }
+  }
+
+  cfg := "[defaults]\nhost_key_checking = True\n"
+  if err := ioutil.WriteFile(ansibleCfgPath, []byte(cfg), 0644); err != nil {
+    return "", "", fmt.Errorf("Error writing ansible.cfg: %s", err)
+  }
+
+  return knownHostsPath, ansibleCfgPath, nil
+}
+
+// scanHostKey seeds a known_hosts file from a live scan of the target's SSH
+// host key, analogous to the host-key verification Terraform's own SSH
+// communicator performs against a negotiated connection.
+func scanHostKey(knownHostsPath string, host string, port string) error {
+  if len(port) == 0 {
+    port = "22"
+  }
+  out, err := exec.Command("ssh-keyscan", "-p", port, host).Output()
+  if err != nil {
+    return fmt.Errorf("Error scanning host key for %s:%s: %s", host, port, err)
+  }
+  return ioutil.WriteFile(knownHostsPath, out, 0644)
+}
+
+// writeLocalKeyFile materializes a private key into tempDir so it can be
+// referenced by path from the rendered inventory. It is a no-op, returning
+// an empty path, when no key material is present (e.g. password auth).
+func writeLocalKeyFile(tempDir string, name string, key string) (string, error) {
+  if len(key) == 0 {
+    return "", nil
+  }
+  path := filepath.Join(tempDir, name)
+  if err := ioutil.WriteFile(path, []byte(key), 0600); err != nil {
+    return "", fmt.Errorf("Error writing %s: %s", path, err)
+  }
+  return path, nil
+}
+
+func (p *provisioner) writeLocalInventory(path string, connInfo map[string]string, privateKeyPath string, bastionKeyPath string, knownHostsPath string) error {
+  port := connInfo["port"]
+  if len(port) == 0 {
+    port = "22"
+  }
+
+  data := &localInventoryData{
+    Host:           connInfo["host"],
+    Port:           port,
+    User:           connInfo["user"],
+    PrivateKeyFile: privateKeyPath,
+    SSHCommonArgs:  sshCommonArgs(connInfo, bastionKeyPath, knownHostsPath, p.hostKeyChecking),
+  }
+
+  t := template.Must(template.New("local-hosts").Parse(inventoryTemplateLocal))
+  var buf bytes.Buffer
+  if err := t.Execute(&buf, data); err != nil {
+    return fmt.Errorf("Error executing 'local-hosts' template: %s", err)
+  }
+  return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// sshCommonArgs builds ansible_ssh_common_args honoring host_key_checking
+// and, from the connection's bastion_* fields, a ProxyCommand so hosts
+// reachable only through a jump host still work in local-exec mode.
+func sshCommonArgs(connInfo map[string]string, bastionKeyPath string, knownHostsPath string, hostKeyChecking bool) string {
+  var hostKeyOpts string
+  if hostKeyChecking {
+    hostKeyOpts = fmt.Sprintf("-o StrictHostKeyChecking=yes -o UserKnownHostsFile=%s", knownHostsPath)
+  } else {
+    hostKeyOpts = "-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null"
+  }
+
+  bastionHost := connInfo["bastion_host"]
+  if len(bastionHost) == 0 {
+    return hostKeyOpts
+  }
+
+  bastionUser := connInfo["bastion_user"]
+  if len(bastionUser) == 0 {
+    bastionUser = connInfo["user"]
+  }
+  bastionPort := connInfo["bastion_port"]
+  if len(bastionPort) == 0 {
+    bastionPort = "22"
+  }
+
+  proxyCommand := fmt.Sprintf("ssh %s -p %s", hostKeyOpts, bastionPort)
+  if len(bastionKeyPath) > 0 {
+    proxyCommand = fmt.Sprintf("%s -i %s", proxyCommand, bastionKeyPath)
+  }
+  proxyCommand = fmt.Sprintf("%s -W %%h:%%p %s@%s", proxyCommand, bastionUser, bastionHost)
+
+  return fmt.Sprintf("%s -o ProxyCommand=\"%s\"", hostKeyOpts, proxyCommand)
+}
+
+// writeLocalInventoryWinRM renders the inventory used in local-exec mode
+// against a WinRM target. connInfo is read using the same keys Terraform's
+// own winrm communicator populates (host, port, user, password, https,
+// insecure, use_ntlm), defaulting the port to 5985/5986 per scheme the way
+// that communicator does.
+func (p *provisioner) writeLocalInventoryWinRM(path string, connInfo map[string]string) error {
+  scheme := "http"
+  port := connInfo["port"]
+  if connInfo["https"] == "true" {
+    scheme = "https"
+    if len(port) == 0 {
+      port = "5986"
+    }
+  } else if len(port) == 0 {
+    port = "5985"
+  }
+
+  transport := "basic"
+  if connInfo["use_ntlm"] == "true" {
+    transport = "ntlm"
+  }
+
+  serverCertValidation := "validate"
+  if connInfo["insecure"] == "true" {
+    serverCertValidation = "ignore"
+  }
+
+  data := &winrmInventoryData{
+    Host:                 connInfo["host"],
+    Port:                 port,
+    User:                 connInfo["user"],
+    Password:             connInfo["password"],
+    Scheme:               scheme,
+    WinRMTransport:       transport,
+    ServerCertValidation: serverCertValidation,
+  }
+
+  t := template.Must(template.New("local-hosts-winrm").Parse(inventoryTemplateLocalWinRM))
+  var buf bytes.Buffer
+  if err := t.Execute(&buf, data); err != nil {
+    return fmt.Errorf("Error executing 'local-hosts-winrm' template: %s", err)
+  }
+  return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// runLocalCommand runs an already-built command on the Terraform host. When
+// capture is non-nil, stdout is also mirrored into it (in addition to being
+// streamed to o as usual) so callers can post-process the full output, e.g.
+// to parse a JSON stdout_callback result.
+func (p *provisioner) runLocalCommand(o terraform.UIOutput, command string, extraEnv []string, capture *bytes.Buffer) error {
+  cmd := exec.Command("/bin/sh", "-c", command)
+  cmd.Env = append(os.Environ(), extraEnv...)
+
+  outR, outW := io.Pipe()
+  errR, errW := io.Pipe()
+  outDoneCh := make(chan struct{})
+  errDoneCh := make(chan struct{})
+  go p.copyOutput(o, outR, outDoneCh)
+  go p.copyOutput(o, errR, errDoneCh)
+  if capture != nil {
+    cmd.Stdout = io.MultiWriter(outW, capture)
+  } else {
+    cmd.Stdout = outW
+  }
+  cmd.Stderr = errW
+
+  err := cmd.Start()
+  if err != nil {
+    return fmt.Errorf("Error starting command %q: %v", command, err)
+  }
+
+  waitErr := cmd.Wait()
+
+  outW.Close()
+  errW.Close()
+  <-outDoneCh
+  <-errDoneCh
+
+  if waitErr != nil {
+    return fmt.Errorf("Command %q exited with error: %v", command, waitErr)
+  }
+  return nil
+}
+
+func (p *provisioner) deployAnsibleModule(o terraform.UIOutput, comm communicator.Communicator) (*ansibleRunResult, error) {
+
+  plays := p.plays()
+
+  // all plays share one uploaded module directory, taken from the first
+  // play's playbook; they're expected to live alongside each other.
+  firstPlaybookPath, err := p.resolvePath(plays[0].Playbook, o)
+  if err != nil {
+    return nil, err
+  }
+  playbookDir := filepath.Dir(firstPlaybookPath)
+
+  remoteBootstrapDir := p.bootstrapDir()
+
+  // upload ansible source and playbooks to the host
+  if err := comm.UploadDir(remoteBootstrapDir, playbookDir); err != nil {
+    return nil, err
+  }
+
+  if len(p.galaxyRequirements) > 0 {
+    galaxyRequirementsPath, err := p.resolvePath(p.galaxyRequirements, o)
+    if err != nil {
+      return nil, err
+    }
+    remoteRequirementsPath := remoteBootstrapDir + "/" + filepath.Base(galaxyRequirementsPath)
+    file, err := os.Open(galaxyRequirementsPath)
+    if err != nil {
+      return nil, err
+    }
+    defer file.Close()
+    if err := comm.Upload(remoteRequirementsPath, bufio.NewReader(file)); err != nil {
+      return nil, err
+    }
+    for _, galaxyCommand := range p.galaxyCommands(remoteRequirementsPath, galaxyRequirementsPath) {
+      o.Output(fmt.Sprintf("running command: %s", galaxyCommand))
+      if err := p.runCommand(o, comm, galaxyCommand); err != nil {
+        return nil, err
+      }
+    }
+  }
+
+  vaultPasswordFilePath := p.VaultPasswordFile
+  uploadedVaultPasswordFilePath := ""
+  if len(vaultPasswordFilePath) > 0 {
+    vaultPasswordFilePath, err = p.resolvePath(vaultPasswordFilePath, o)
+    if err != nil {
+      return nil, err
+    }
+    uploadedVaultPasswordFilePath, err = p.uploadVaultPasswordFile(o, comm, vaultPasswordFilePath)
+    if err != nil {
+      return nil, err
+    }
   }
 
   // create temp inventory:
   if err = p.uploadInventory(o, comm); err != nil {
-    return err
+    return nil, err
   }
 
-  o.Output(fmt.Sprintf("running command: %s", command))
-  if err := p.runCommand(o, comm, command); err != nil {
-    return err
+  var result *ansibleRunResult
+  for i, pl := range plays {
+    playbookPath, err := p.resolvePath(pl.Playbook, o)
+    if err != nil {
+      return result, err
+    }
+    remotePlaybookPath := remoteBootstrapDir + "/" + filepath.Base(playbookPath)
+
+    command, err := p.commandBuilder(pl, remotePlaybookPath, p.inventoryPath(), uploadedVaultPasswordFilePath)
+    if err != nil {
+      return result, err
+    }
+
+    o.Output(fmt.Sprintf("running command (play %d/%d): %s", i+1, len(plays), command))
+
+    var capture *bytes.Buffer
+    if len(p.stdoutCallback) > 0 {
+      capture = &bytes.Buffer{}
+    }
+    runErr := p.runCommandCapture(o, comm, command, capture)
+
+    playResult, parseErr := p.finishCallbackOutput(o, capture)
+    if parseErr != nil {
+      o.Output(fmt.Sprintf("Warning: could not parse ansible %s output: %s", p.stdoutCallback, parseErr))
+    }
+    result = mergeAnsibleResult(result, playResult)
+
+    if runErr != nil {
+      if pl.ContinueOnError {
+        o.Output(fmt.Sprintf("play %d/%d failed, continuing because continue_on_error is set: %s", i+1, len(plays), runErr))
+        continue
+      }
+      return result, runErr
+    }
   }
 
   if !p.skipCleanup {
     p.cleanupAfterBootstrap(o, comm)
   }
 
-  return nil
+  return result, nil
+}
+
+// mergeAnsibleResult accumulates per-play ansibleRunResults (from
+// successive `plays` blocks) into a single summary.
+func mergeAnsibleResult(acc *ansibleRunResult, next *ansibleRunResult) *ansibleRunResult {
+  if next == nil {
+    return acc
+  }
+  if acc == nil {
+    return next
+  }
+  acc.OK += next.OK
+  acc.Changed += next.Changed
+  acc.Failed += next.Failed
+  acc.Unreachable += next.Unreachable
+  acc.Skipped += next.Skipped
+  if len(next.RawOutput) > 0 {
+    if len(acc.RawOutput) > 0 {
+      acc.RawOutput += "\n"
+    }
+    acc.RawOutput += next.RawOutput
+  }
+  return acc
+}
+
+// finishCallbackOutput persists a captured ansible-playbook run (when
+// callback_output_path is set) and, for the JSON callback, parses per-host
+// stats into an ansibleRunResult. A nil/empty capture (no stdout_callback
+// configured) is a no-op.
+func (p *provisioner) finishCallbackOutput(o terraform.UIOutput, capture *bytes.Buffer) (*ansibleRunResult, error) {
+  if capture == nil || capture.Len() == 0 {
+    return nil, nil
+  }
+  raw := capture.String()
+
+  if len(p.callbackOutputPath) > 0 {
+    if err := ioutil.WriteFile(p.callbackOutputPath, []byte(raw), 0644); err != nil {
+      o.Output(fmt.Sprintf("Warning: could not write callback output to %s: %s", p.callbackOutputPath, err))
+    }
+  }
+
+  if p.stdoutCallback != "json" {
+    // yaml (or any other) callback output is captured and persisted above,
+    // but only the json callback is parsed into per-host stats here.
+    return &ansibleRunResult{RawOutput: raw}, nil
+  }
+
+  return parseAnsibleJSONOutput(o, raw)
+}
+
+// parseAnsibleJSONOutput sums the per-host "stats" block of ansible's json
+// callback output and emits a compact human summary line per host.
+func parseAnsibleJSONOutput(o terraform.UIOutput, raw string) (*ansibleRunResult, error) {
+  var callback ansibleJSONCallback
+  if err := json.Unmarshal([]byte(raw), &callback); err != nil {
+    return &ansibleRunResult{RawOutput: raw}, err
+  }
+
+  result := &ansibleRunResult{RawOutput: raw}
+  for host, stats := range callback.Stats {
+    result.OK += stats.Ok
+    result.Changed += stats.Changed
+    result.Failed += stats.Failures
+    result.Unreachable += stats.Unreachable
+    result.Skipped += stats.Skipped
+    o.Output(fmt.Sprintf("%s : ok=%d changed=%d unreachable=%d failed=%d skipped=%d",
+      host, stats.Ok, stats.Changed, stats.Unreachable, stats.Failures, stats.Skipped))
+  }
+  return result, nil
 }
 
+// installAnsible is only ever reached for POSIX targets: WinRM targets are
+// always redirected to the local execution path in applyFn, since Ansible
+// cannot run on the Windows target itself.
 func (p *provisioner) installAnsible(o terraform.UIOutput, comm communicator.Communicator) error {
+  return p.installAnsibleUnix(o, comm)
+}
+
+// installAnsibleUnix dispatches to the template backing install_method.
+// pip is the long-standing default; pip_venv, package and pipx exist to
+// work around modern distros (Debian 11+/Ubuntu 22.04+ dropping python-dev,
+// PEP 668's externally-managed-environment pip lockout, Alpine, Amazon
+// Linux 2023, ...) where a bare system-wide `pip install ansible` no
+// longer works. custom_script hands the whole job to a user-supplied script.
+func (p *provisioner) installAnsibleUnix(o terraform.UIOutput, comm communicator.Communicator) error {
+  if p.installMethod == "custom_script" {
+    return p.installAnsibleCustomScript(o, comm)
+  }
+
+  packageName := "ansible"
+  tmplSrc := installerProgramTemplate
+  switch p.installMethod {
+  case "pip_venv":
+    packageName = "ansible-core"
+    tmplSrc = installerProgramTemplatePipVenv
+  case "package":
+    tmplSrc = installerProgramTemplatePackage
+  case "pipx":
+    tmplSrc = installerProgramTemplatePipx
+  }
+
+  if p.installMethod == "package" && len(p.installVersion) > 0 {
+    o.Output(fmt.Sprintf("Warning: install_version (%s) is ignored when install_method=package; the distro's package manager installs whatever version its repos carry.", p.installVersion))
+  }
 
   installer := &ansibleInstaller{
-    AnsibleVersion: "ansible",
+    AnsibleVersion: packageName,
   }
   if len(p.installVersion) > 0 {
     installer.AnsibleVersion = fmt.Sprintf("%s==%s", installer.AnsibleVersion, p.installVersion)
   }
 
-  o.Output(fmt.Sprintf("Installing '%s'...", installer.AnsibleVersion))
+  o.Output(fmt.Sprintf("Installing '%s' (install_method=%s)...", installer.AnsibleVersion, p.installMethod))
 
-  t := template.Must(template.New("installer").Parse(installerProgramTemplate))
+  t := template.Must(template.New("installer").Parse(tmplSrc))
   var buf bytes.Buffer
   err := t.Execute(&buf, installer)
   if err != nil {
@@ -346,10 +1258,39 @@ func (p *provisioner) installAnsible(o terraform.UIOutput, comm communicator.Com
   return nil
 }
 
+// installAnsibleCustomScript uploads and runs a user-supplied install
+// script verbatim, for environments none of the built-in install_method
+// templates fit.
+func (p *provisioner) installAnsibleCustomScript(o terraform.UIOutput, comm communicator.Communicator) error {
+  scriptPath, err := p.resolvePath(p.customInstallScript, o)
+  if err != nil {
+    return err
+  }
+
+  file, err := os.Open(scriptPath)
+  if err != nil {
+    return err
+  }
+  defer file.Close()
+
+  targetPath := "/tmp/ansible-install.sh"
+  o.Output(fmt.Sprintf("Uploading custom ansible installer program to %s...", targetPath))
+  if err := comm.UploadScript(targetPath, bufio.NewReader(file)); err != nil {
+    return err
+  }
+
+  if err := p.runCommand(o, comm, fmt.Sprintf("/bin/bash -c '%s && rm %s'", targetPath, targetPath)); err != nil {
+    return err
+  }
+
+  o.Output("Ansible installed.")
+  return nil
+}
+
 func (p *provisioner) uploadVaultPasswordFile(o terraform.UIOutput, comm communicator.Communicator, passwordFilePath string) (string, error) {
 
   passwordFileName := filepath.Base(passwordFilePath)
-  targetPath := filepath.Join(bootstrapDirectory, ".vault-ansible-bootstrap", passwordFileName)
+  targetPath := p.bootstrapDir() + "/.vault-ansible-bootstrap/" + passwordFileName
 
   commands := []string{
     fmt.Sprintf("mkdir -p %s", filepath.Dir(targetPath)),
@@ -386,7 +1327,7 @@ func (p *provisioner) uploadInventory(o terraform.UIOutput, comm communicator.Co
   if err != nil {
     return fmt.Errorf("Error executing 'hosts' template: %s", err)
   }
-  targetPath := inventoryFilePath
+  targetPath := p.inventoryPath()
 
   commands := []string{
     fmt.Sprintf("mkdir -p %s", filepath.Dir(targetPath)),
@@ -411,31 +1352,47 @@ func (p *provisioner) cleanupAfterBootstrap(o terraform.UIOutput, comm communica
   o.Output("Cleanup complete.")
 }
 
-func (p *provisioner) commandBuilder(playbookFile string, uploadedVaultPasswordFilePath string) (string, error) {
+// bootstrapDir returns the remote directory the playbook and its supporting
+// files are uploaded to. Only ever used on the remote (non-local) execution
+// path, which WinRM targets no longer take, so it's always the POSIX path.
+func (p *provisioner) bootstrapDir() string {
+  return bootstrapDirectory
+}
+
+// inventoryPath returns the remote path of the generated inventory file.
+func (p *provisioner) inventoryPath() string {
+  return inventoryFilePath
+}
+
+// commandBuilder assembles the ansible-playbook invocation. It is shared by
+// the remote (upload-and-run-on-target) and local (run-on-the-Terraform-host)
+// execution paths, which differ only in where the playbook and inventory
+// live, and is called once per play when `plays` blocks are configured.
+func (p *provisioner) commandBuilder(pl *play, playbookFile string, inventoryPath string, uploadedVaultPasswordFilePath string) (string, error) {
 
   command := fmt.Sprintf("ansible-playbook %s", playbookFile)
-  command = fmt.Sprintf("%s --inventory-file=%s", command, inventoryFilePath)
-  if len(p.ExtraVars) > 0 {
-    extraVars, err := json.Marshal(p.ExtraVars)
+  command = fmt.Sprintf("%s --inventory-file=%s", command, inventoryPath)
+  if len(pl.ExtraVars) > 0 {
+    extraVars, err := json.Marshal(pl.ExtraVars)
     if err != nil {
       return "", err
     }
     command = fmt.Sprintf("%s --extra-vars='%s'", command, string(extraVars))
   }
-  if len(p.SkipTags) > 0 {
-    command = fmt.Sprintf("%s --skip-tags=%s", command, strings.Join(p.SkipTags, ","))
+  if len(pl.SkipTags) > 0 {
+    command = fmt.Sprintf("%s --skip-tags=%s", command, strings.Join(pl.SkipTags, ","))
   }
-  if len(p.Tags) > 0 {
-    command = fmt.Sprintf("%s --tags=%s", command, strings.Join(p.Tags, ","))
+  if len(pl.Tags) > 0 {
+    command = fmt.Sprintf("%s --tags=%s", command, strings.Join(pl.Tags, ","))
   }
   if len(uploadedVaultPasswordFilePath) > 0 {
     command = fmt.Sprintf("%s --vault-password-file=%s", command, uploadedVaultPasswordFilePath)
   }
-  if len(p.StartAtTask) > 0 {
-    command = fmt.Sprintf("%s --start-at-task=%s", command, p.StartAtTask)
+  if len(pl.StartAtTask) > 0 {
+    command = fmt.Sprintf("%s --start-at-task=%s", command, pl.StartAtTask)
   }
-  if len(p.Limit) > 0 {
-    command = fmt.Sprintf("%s --limit=%s", command, p.Limit)
+  if len(pl.Limit) > 0 {
+    command = fmt.Sprintf("%s --limit=%s", command, pl.Limit)
   }
   if p.Forks > 0 {
     command = fmt.Sprintf("%s --forks=%d", command, p.Forks)
@@ -449,6 +1406,9 @@ func (p *provisioner) commandBuilder(playbookFile string, uploadedVaultPasswordF
   if p.Become {
     command = fmt.Sprintf("%s --become --become-method='%s' --become-user='%s'", command, p.BecomeMethod, p.BecomeUser)
   }
+  if len(p.pythonInterpreter) > 0 {
+    command = fmt.Sprintf("%s -e ansible_python_interpreter=%s", command, p.pythonInterpreter)
+  }
   return command, nil
 }
 
@@ -462,6 +1422,29 @@ func (p *provisioner) resolvePath(path string, o terraform.UIOutput) (string, er
 
 // runCommand is used to run already prepared commands
 func (p *provisioner) runCommand(o terraform.UIOutput, comm communicator.Communicator, command string) error {
+  return p.runCommandCapture(o, comm, command, nil)
+}
+
+// runCommandCapture behaves like runCommand, except that when capture is
+// non-nil stdout is also mirrored into it (in addition to being streamed to
+// o as usual), so callers can post-process the full output, e.g. to parse a
+// JSON stdout_callback result. Only ever used on the remote (non-local)
+// execution path, which WinRM targets no longer take, so comm here is
+// always a POSIX (SSH) communicator.
+//
+// stdout_callback is applied here, as an actual environment variable for the
+// process ansible-playbook runs as, rather than as a "VAR=val" textual
+// prefix on the command: prefixing a "sudo ..." command that way only sets
+// the variable for the sudo invocation itself, and whether it's then
+// forwarded to the child process depends on the target's sudoers
+// env_reset/env_keep policy (stripped on a default Debian/Ubuntu config).
+// Routing it through `env` instead sets it directly on ansible-playbook's
+// own exec, independent of sudo's environment policy.
+func (p *provisioner) runCommandCapture(o terraform.UIOutput, comm communicator.Communicator, command string, capture *bytes.Buffer) error {
+  if len(p.stdoutCallback) > 0 {
+    command = fmt.Sprintf("env ANSIBLE_STDOUT_CALLBACK=%s %s", p.stdoutCallback, command)
+  }
+
   // Unless prevented, prefix the command with sudo
   if p.useSudo {
     command = "sudo " + command
@@ -474,9 +1457,14 @@ func (p *provisioner) runCommand(o terraform.UIOutput, comm communicator.Communi
   go p.copyOutput(o, outR, outDoneCh)
   go p.copyOutput(o, errR, errDoneCh)
 
+  var stdout io.Writer = outW
+  if capture != nil {
+    stdout = io.MultiWriter(outW, capture)
+  }
+
   cmd := &remote.Cmd{
     Command: command,
-    Stdout:  outW,
+    Stdout:  stdout,
     Stderr:  errW,
   }
 
@@ -529,6 +1517,7 @@ func retryFunc(timeout time.Duration, f func() error) error {
 func decodeConfig(d *schema.ResourceData) (*provisioner, error) {
   p := &provisioner{
     Playbook:          d.Get("playbook").(string),
+    Plays:             decodePlays(d.Get("plays")),
     Hosts:             getStringList(d.Get("hosts")),
     Groups:            getStringList(d.Get("groups")),
     Tags:              getStringList(d.Get("tags")),
@@ -550,11 +1539,74 @@ func decodeConfig(d *schema.ResourceData) (*provisioner, error) {
     skipInstall:       d.Get("skip_install").(bool),
     skipCleanup:       d.Get("skip_cleanup").(bool),
     installVersion:    d.Get("install_version").(string),
+    local:             d.Get("local").(bool),
+    hostKeyChecking:   d.Get("host_key_checking").(bool),
+    knownHosts:        d.Get("known_hosts").(string),
+
+    galaxyRequirements:    d.Get("galaxy_requirements").(string),
+    galaxyRolesPath:       d.Get("galaxy_roles_path").(string),
+    galaxyCollectionsPath: d.Get("galaxy_collections_path").(string),
+    galaxyForce:           d.Get("galaxy_force").(bool),
+    galaxyIgnoreCerts:     d.Get("galaxy_ignore_certs").(bool),
+
+    stdoutCallback:      d.Get("stdout_callback").(string),
+    callbackOutputPath:  d.Get("callback_output_path").(string),
+
+    installMethod:       d.Get("install_method").(string),
+    customInstallScript: d.Get("custom_install_script").(string),
+    pythonInterpreter:   d.Get("python_interpreter").(string),
   }
   p.Hosts = append(p.Hosts, "localhost")
   return p, nil
 }
 
+// decodePlays reads the `plays` block list into []*play, returning nil when
+// none are configured so the caller falls back to the legacy single-playbook
+// fields instead.
+func decodePlays(v interface{}) []*play {
+  raw, ok := v.([]interface{})
+  if !ok || len(raw) == 0 {
+    return nil
+  }
+
+  plays := make([]*play, 0, len(raw))
+  for _, item := range raw {
+    m, ok := item.(map[string]interface{})
+    if !ok {
+      continue
+    }
+    plays = append(plays, &play{
+      Playbook:        m["playbook"].(string),
+      Tags:            getStringList(m["tags"]),
+      SkipTags:        getStringList(m["skip_tags"]),
+      StartAtTask:     m["start_at_task"].(string),
+      Limit:           m["limit"].(string),
+      ExtraVars:       getStringMap(m["extra_vars"]),
+      ContinueOnError: m["continue_on_error"].(bool),
+    })
+  }
+  return plays
+}
+
+// plays returns the plays to execute, in order: the configured `plays`
+// blocks if any, otherwise the legacy top-level Playbook/Tags/... fields
+// wrapped into a single implicit play.
+func (p *provisioner) plays() []*play {
+  if len(p.Plays) > 0 {
+    return p.Plays
+  }
+  return []*play{
+    {
+      Playbook:    p.Playbook,
+      Tags:        p.Tags,
+      SkipTags:    p.SkipTags,
+      StartAtTask: p.StartAtTask,
+      Limit:       p.Limit,
+      ExtraVars:   p.ExtraVars,
+    },
+  }
+}
+
 func getStringList(v interface{}) []string {
   var result []string
   switch v := v.(type) {
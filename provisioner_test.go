@@ -0,0 +1,100 @@
+package main
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestCommandBuilder(t *testing.T) {
+  p := &provisioner{}
+  pl := &play{
+    Playbook: "playbook.yml",
+    Tags:     []string{"web"},
+    SkipTags: []string{"slow"},
+  }
+
+  command, err := p.commandBuilder(pl, "/tmp/playbook.yml", "/tmp/hosts", "")
+  if err != nil {
+    t.Fatalf("commandBuilder returned an error: %s", err)
+  }
+
+  for _, want := range []string{
+    "ansible-playbook /tmp/playbook.yml",
+    "--inventory-file=/tmp/hosts",
+    "--tags=web",
+    "--skip-tags=slow",
+  } {
+    if !strings.Contains(command, want) {
+      t.Errorf("expected command to contain %q, got: %s", want, command)
+    }
+  }
+}
+
+func TestCommandBuilderVaultPasswordFile(t *testing.T) {
+  p := &provisioner{}
+  pl := &play{Playbook: "playbook.yml"}
+
+  command, err := p.commandBuilder(pl, "/tmp/playbook.yml", "/tmp/hosts", "/tmp/vault-pass")
+  if err != nil {
+    t.Fatalf("commandBuilder returned an error: %s", err)
+  }
+
+  if !strings.Contains(command, "--vault-password-file=/tmp/vault-pass") {
+    t.Errorf("expected command to reference the uploaded vault password file, got: %s", command)
+  }
+}
+
+func TestGalaxyInstallCommandRoles(t *testing.T) {
+  p := &provisioner{galaxyRolesPath: "/roles", galaxyCollectionsPath: "/collections"}
+
+  command := p.galaxyInstallCommand("", "/tmp/requirements.yml")
+
+  if !strings.Contains(command, "--roles-path=/roles") {
+    t.Errorf("expected role install to use --roles-path, got: %s", command)
+  }
+  if strings.Contains(command, "--collections-path") {
+    t.Errorf("role install must not reference --collections-path, got: %s", command)
+  }
+}
+
+func TestGalaxyInstallCommandCollections(t *testing.T) {
+  p := &provisioner{galaxyRolesPath: "/roles", galaxyCollectionsPath: "/collections"}
+
+  command := p.galaxyInstallCommand("collection", "/tmp/requirements.yml")
+
+  if !strings.Contains(command, "ansible-galaxy collection install") {
+    t.Errorf("expected a collection install command, got: %s", command)
+  }
+  if !strings.Contains(command, "--collections-path=/collections") {
+    t.Errorf("expected collection install to use --collections-path, got: %s", command)
+  }
+  if strings.Contains(command, "--roles-path") {
+    t.Errorf("collection install must not reference --roles-path (unrecognized by that subcommand), got: %s", command)
+  }
+}
+
+func TestGalaxyCommandsSkipsCollectionsWhenAbsent(t *testing.T) {
+  p := &provisioner{}
+
+  commands := p.galaxyCommands("/tmp/requirements.yml", "testdata/requirements_roles_only.yml")
+
+  if len(commands) != 1 {
+    t.Fatalf("expected a single role install command, got: %v", commands)
+  }
+  if !strings.Contains(commands[0], "ansible-galaxy install") {
+    t.Errorf("expected a plain role install, got: %s", commands[0])
+  }
+}
+
+func TestGalaxyCommandsIncludesCollectionsWhenPresent(t *testing.T) {
+  p := &provisioner{}
+
+  commands := p.galaxyCommands("/tmp/requirements.yml", "testdata/requirements_with_collections.yml")
+
+  if len(commands) != 2 {
+    t.Fatalf("expected a role install and a collection install, got: %v", commands)
+  }
+  if !strings.Contains(commands[1], "ansible-galaxy collection install") {
+    t.Errorf("expected the second command to be a collection install, got: %s", commands[1])
+  }
+}